@@ -0,0 +1,166 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+// Package annotations centralizes parsing and validation of the
+// `appgw.ingress.kubernetes.io/*` annotations this controller understands.
+package annotations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/api/extensions/v1beta1"
+)
+
+const prefix = "appgw.ingress.kubernetes.io"
+
+// Annotation keys recognized on Ingress resources.
+const (
+	HealthProbeStatusCodesKey                     = prefix + "/health-probe-status-codes"
+	HealthProbeIntervalKey                        = prefix + "/health-probe-interval"
+	HealthProbeTimeoutKey                         = prefix + "/health-probe-timeout"
+	HealthProbeUnhealthyThresholdKey              = prefix + "/health-probe-unhealthy-threshold"
+	HealthProbePathKey                            = prefix + "/health-probe-path"
+	HealthProbeHostNameKey                        = prefix + "/health-probe-hostname"
+	HealthProbeBodyMatchKey                       = prefix + "/health-probe-body-match"
+	HealthProbePickHostFromBackendHTTPSettingsKey = prefix + "/health-probe-pick-host-from-backend-http-settings"
+
+	BackendProtocolKey = prefix + "/backend-protocol"
+
+	IngressClassKey = "kubernetes.io/ingress.class"
+)
+
+// ARM-imposed bounds on ApplicationGatewayProbePropertiesFormat's numeric fields.
+const (
+	minProbeInterval           = 1
+	maxProbeInterval           = 86400
+	minProbeTimeout            = 1
+	maxProbeTimeout            = 86400
+	minProbeUnhealthyThreshold = 1
+	maxProbeUnhealthyThreshold = 20
+)
+
+// ErrMissingAnnotation is returned by the getters below when the requested annotation is
+// not present on the Ingress; callers treat it as "use the default" rather than a failure.
+var ErrMissingAnnotation = fmt.Errorf("annotation not present")
+
+// IngressClass returns the `kubernetes.io/ingress.class` annotation on ing, if any.
+func IngressClass(ing *v1beta1.Ingress) (string, error) {
+	return parseString(ing, IngressClassKey)
+}
+
+// HealthProbeStatusCodes returns the raw value of the health-probe-status-codes
+// annotation, e.g. "200-399" or "200,204".
+func HealthProbeStatusCodes(ing *v1beta1.Ingress) (string, error) {
+	value, err := parseString(ing, HealthProbeStatusCodesKey)
+	if err != nil {
+		return "", err
+	}
+	if err := validateStatusCodes(value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// HealthProbeInterval returns the health-probe-interval annotation in seconds.
+func HealthProbeInterval(ing *v1beta1.Ingress) (int32, error) {
+	return parseBoundedInt32(ing, HealthProbeIntervalKey, minProbeInterval, maxProbeInterval)
+}
+
+// HealthProbeTimeout returns the health-probe-timeout annotation in seconds.
+func HealthProbeTimeout(ing *v1beta1.Ingress) (int32, error) {
+	return parseBoundedInt32(ing, HealthProbeTimeoutKey, minProbeTimeout, maxProbeTimeout)
+}
+
+// HealthProbeUnhealthyThreshold returns the health-probe-unhealthy-threshold annotation.
+func HealthProbeUnhealthyThreshold(ing *v1beta1.Ingress) (int32, error) {
+	return parseBoundedInt32(ing, HealthProbeUnhealthyThresholdKey, minProbeUnhealthyThreshold, maxProbeUnhealthyThreshold)
+}
+
+// HealthProbePath returns the health-probe-path annotation.
+func HealthProbePath(ing *v1beta1.Ingress) (string, error) {
+	return parseString(ing, HealthProbePathKey)
+}
+
+// HealthProbeHostName returns the health-probe-hostname annotation.
+func HealthProbeHostName(ing *v1beta1.Ingress) (string, error) {
+	return parseString(ing, HealthProbeHostNameKey)
+}
+
+// HealthProbeBodyMatch returns the health-probe-body-match annotation.
+func HealthProbeBodyMatch(ing *v1beta1.Ingress) (string, error) {
+	return parseString(ing, HealthProbeBodyMatchKey)
+}
+
+// HealthProbePickHostFromBackendHTTPSettings returns the
+// health-probe-pick-host-from-backend-http-settings annotation: when true, the
+// generated probe's Host is left unset and PickHostNameFromBackendHTTPSettings is
+// set instead, so Application Gateway derives the probe Host from the backend
+// HTTP settings at request time.
+func HealthProbePickHostFromBackendHTTPSettings(ing *v1beta1.Ingress) (bool, error) {
+	return parseBool(ing, HealthProbePickHostFromBackendHTTPSettingsKey)
+}
+
+// BackendProtocol returns the backend-protocol annotation, e.g. "http" or "https".
+func BackendProtocol(ing *v1beta1.Ingress) (string, error) {
+	return parseString(ing, BackendProtocolKey)
+}
+
+func parseString(ing *v1beta1.Ingress, name string) (string, error) {
+	value, ok := ing.Annotations[name]
+	if !ok {
+		return "", ErrMissingAnnotation
+	}
+	return value, nil
+}
+
+func parseBool(ing *v1beta1.Ingress, name string) (bool, error) {
+	raw, err := parseString(ing, name)
+	if err != nil {
+		return false, err
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("annotation %s: %q is not a valid boolean", name, raw)
+	}
+	return value, nil
+}
+
+func parseBoundedInt32(ing *v1beta1.Ingress, name string, min, max int32) (int32, error) {
+	raw, err := parseString(ing, name)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("annotation %s: %q is not a valid integer", name, raw)
+	}
+	value := int32(parsed)
+	if value < min || value > max {
+		return 0, fmt.Errorf("annotation %s: %d is out of range [%d, %d]", name, value, min, max)
+	}
+	return value, nil
+}
+
+// validateStatusCodes checks that value is a comma-separated list of either single HTTP
+// status codes ("200") or inclusive ranges ("200-399"), each within the valid HTTP status
+// code range.
+func validateStatusCodes(value string) error {
+	for _, code := range strings.Split(value, ",") {
+		code = strings.TrimSpace(code)
+		bounds := strings.SplitN(code, "-", 2)
+		for _, bound := range bounds {
+			n, err := strconv.Atoi(bound)
+			if err != nil {
+				return fmt.Errorf("annotation %s: %q is not a valid status code", HealthProbeStatusCodesKey, code)
+			}
+			if n < 100 || n > 599 {
+				return fmt.Errorf("annotation %s: %q is outside the valid HTTP status code range", HealthProbeStatusCodesKey, code)
+			}
+		}
+	}
+	return nil
+}
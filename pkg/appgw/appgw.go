@@ -0,0 +1,89 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-12-01/network"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+)
+
+// DefaultIngressClass is the value of the `kubernetes.io/ingress.class` annotation that this
+// controller acts on when no other value has been configured.
+const DefaultIngressClass = "azure/application-gateway"
+
+// k8sCaches bundles the informer caches the ConfigBuilder reads from when translating
+// Kubernetes resources into Application Gateway configuration.
+type k8sCaches struct {
+	Endpoints cache.Store
+	Service   cache.Store
+	Pods      cache.Store
+	Ingress   cache.Store
+}
+
+// k8sContext is the minimal view of the cluster the ConfigBuilder needs in order to build an
+// Application Gateway configuration.
+type k8sContext struct {
+	Caches k8sCaches
+}
+
+// newK8sContext constructs a k8sContext backed by empty in-memory caches.
+func newK8sContext() *k8sContext {
+	return &k8sContext{
+		Caches: k8sCaches{
+			Endpoints: cache.NewStore(cache.MetaNamespaceKeyFunc),
+			Service:   cache.NewStore(cache.MetaNamespaceKeyFunc),
+			Pods:      cache.NewStore(cache.MetaNamespaceKeyFunc),
+			Ingress:   cache.NewStore(cache.MetaNamespaceKeyFunc),
+		},
+	}
+}
+
+// ConfigBuilder assembles an ApplicationGatewayPropertiesFormat from the Ingress resources
+// observed in the cluster.
+type ConfigBuilder struct {
+	k8sContext *k8sContext
+
+	// appGwConfig accumulates the configuration produced by the various *Collection methods.
+	appGwConfig network.ApplicationGatewayPropertiesFormat
+
+	// ingressClass is the value of `kubernetes.io/ingress.class` this ConfigBuilder acts on.
+	// Ingresses annotated with a different class are ignored.
+	ingressClass string
+}
+
+// NewConfigBuilder creates a ConfigBuilder that only considers Ingress resources annotated
+// with the given ingress class (or left unannotated). If ingressClass is "", it falls back
+// to the `--ingress-class` flag / AGIC_INGRESS_CLASS environment variable (see
+// environment.GetIngressClass), and then to DefaultIngressClass.
+func NewConfigBuilder(ingressClass string) *ConfigBuilder {
+	if ingressClass == "" {
+		ingressClass = environment.GetIngressClass()
+	}
+	if ingressClass == "" {
+		ingressClass = DefaultIngressClass
+	}
+	return &ConfigBuilder{
+		k8sContext:   newK8sContext(),
+		ingressClass: ingressClass,
+	}
+}
+
+// ingressMatchesClass reports whether ingress should be acted on by this ConfigBuilder:
+// unannotated Ingresses always match (so a single controller works out of the box), and
+// annotated ones match only when they name this ConfigBuilder's ingress class. This lets
+// multiple ingress controllers coexist in one cluster, each owning a disjoint slice of
+// Ingress resources.
+func (cb *ConfigBuilder) ingressMatchesClass(ingress *v1beta1.Ingress) bool {
+	class, err := annotations.IngressClass(ingress)
+	if err == annotations.ErrMissingAnnotation {
+		return true
+	}
+	return class == cb.ingressClass
+}
@@ -0,0 +1,82 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	testFixturesName      = "-name-"
+	testFixturesHost      = "foo.contoso.com"
+	testFixturesOtherHost = "bar.contoso.com"
+	testFixturesPath      = "/a/b/c/d/e"
+)
+
+// makeConfigBuilderTestFixture returns a ConfigBuilder wired up with empty, in-memory caches,
+// ready to have Services/Endpoints/Pods/Ingresses added to it by individual specs.
+// extraObjects is reserved for specs that need to seed additional state up front.
+func makeConfigBuilderTestFixture(extraObjects []runtime.Object) *ConfigBuilder {
+	cb := NewConfigBuilder(DefaultIngressClass)
+	for _, obj := range extraObjects {
+		_ = cb.k8sContext.Caches.Ingress.Add(obj)
+	}
+	return cb
+}
+
+// makeIngressTestFixture builds the Ingress used across the health-probe specs: one
+// catch-all default-backend rule on service port 80, plus host-based rules pointing at
+// service ports 8080 and 8989.
+func makeIngressTestFixture() v1beta1.Ingress {
+	return v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testFixturesName,
+		},
+		Spec: v1beta1.IngressSpec{
+			Backend: &v1beta1.IngressBackend{
+				ServiceName: testFixturesName,
+				ServicePort: intstr.IntOrString{IntVal: 80},
+			},
+			Rules: []v1beta1.IngressRule{
+				{
+					Host: testFixturesHost,
+					IngressRuleValue: v1beta1.IngressRuleValue{
+						HTTP: &v1beta1.HTTPIngressRuleValue{
+							Paths: []v1beta1.HTTPIngressPath{
+								{
+									Path: testFixturesPath,
+									Backend: v1beta1.IngressBackend{
+										ServiceName: testFixturesName,
+										ServicePort: intstr.IntOrString{IntVal: 8080},
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Host: testFixturesOtherHost,
+					IngressRuleValue: v1beta1.IngressRuleValue{
+						HTTP: &v1beta1.HTTPIngressRuleValue{
+							Paths: []v1beta1.HTTPIngressPath{
+								{
+									Path: testFixturesPath,
+									Backend: v1beta1.IngressBackend{
+										ServiceName: testFixturesName,
+										ServicePort: intstr.IntOrString{IntVal: 8989},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
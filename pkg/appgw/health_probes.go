@@ -0,0 +1,398 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-12-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+)
+
+const (
+	defaultProbeName                     = "k8s-ag-ingress-defaultprobe"
+	defaultProbePath                     = "/"
+	defaultProbeHost                     = "localhost"
+	defaultProbeInterval           int32 = 30
+	defaultProbeTimeout            int32 = 30
+	defaultProbeUnhealthyThreshold int32 = 3
+)
+
+// HealthProbesCollection walks the given Ingress resources and produces the Application
+// Gateway health probes they imply: a single default probe, built from the first Ingress
+// that has a default backend, plus one probe per rule/path combination. Where the rule's
+// backend Service targets a Pod with an HTTP readiness (or, failing that, liveness) probe,
+// that probe's settings are translated onto the generated ApplicationGatewayProbe;
+// otherwise the ARM defaults (Interval/Timeout 30s, UnhealthyThreshold 3) apply. In both
+// cases the Ingress's `health-probe-*` annotations override the result.
+func (cb *ConfigBuilder) HealthProbesCollection(ingressList []*v1beta1.Ingress) (*[]network.ApplicationGatewayProbe, error) {
+	probes := make([]network.ApplicationGatewayProbe, 0)
+	haveDefault := false
+
+	for _, ingress := range ingressList {
+		if !cb.ingressMatchesClass(ingress) {
+			continue
+		}
+
+		if ingress.Spec.Backend != nil && !haveDefault {
+			probe, err := cb.defaultHealthProbe(ingress)
+			if err != nil {
+				return nil, err
+			}
+			probes = append(probes, probe)
+			haveDefault = true
+		}
+
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				probe, err := cb.healthProbeForPath(ingress, rule, path)
+				if err != nil {
+					return nil, err
+				}
+				probes = append(probes, probe)
+			}
+		}
+	}
+
+	cb.appGwConfig.Probes = &probes
+	return &probes, nil
+}
+
+// defaultHealthProbe is the probe used whenever an Ingress carries a default backend:
+// there is no host/path to derive settings from, so the ARM defaults apply unless this
+// Ingress's `health-probe-*` annotations say otherwise.
+func (cb *ConfigBuilder) defaultHealthProbe(ingress *v1beta1.Ingress) (network.ApplicationGatewayProbe, error) {
+	props := &network.ApplicationGatewayProbePropertiesFormat{
+		Protocol:           network.ApplicationGatewayProtocol("Http"),
+		Host:               to.StringPtr(defaultProbeHost),
+		Path:               to.StringPtr(defaultProbePath),
+		Interval:           to.Int32Ptr(defaultProbeInterval),
+		Timeout:            to.Int32Ptr(defaultProbeTimeout),
+		UnhealthyThreshold: to.Int32Ptr(defaultProbeUnhealthyThreshold),
+	}
+
+	if err := applyHealthProbeAnnotations(ingress, props); err != nil {
+		return network.ApplicationGatewayProbe{}, err
+	}
+
+	return network.ApplicationGatewayProbe{
+		Name:                                    to.StringPtr(defaultProbeName),
+		ApplicationGatewayProbePropertiesFormat: props,
+	}, nil
+}
+
+// healthProbeForPath builds the probe for a single Ingress rule/path. Settings are
+// layered with increasing precedence: the ARM defaults, then the backing Pod's own
+// readiness/liveness probe (if any), then this Ingress's `health-probe-*` annotations,
+// which always win since the user set them explicitly.
+func (cb *ConfigBuilder) healthProbeForPath(ingress *v1beta1.Ingress, rule v1beta1.IngressRule, path v1beta1.HTTPIngressPath) (network.ApplicationGatewayProbe, error) {
+	host := rule.Host
+	if host == "" {
+		host = defaultProbeHost
+	}
+
+	probePath := path.Path
+	if probePath == "" {
+		probePath = defaultProbePath
+	}
+
+	protocol := network.ApplicationGatewayProtocol("Http")
+	if svcPort, ok := cb.findServicePort(ingress.Namespace, path.Backend); ok && isHTTPSServicePort(svcPort) {
+		protocol = "Https"
+	}
+	backendProtocolSet := false
+	if backendProtocol, err := annotations.BackendProtocol(ingress); err == nil {
+		backendProtocolSet = true
+		if strings.EqualFold(backendProtocol, "https") {
+			protocol = "Https"
+		} else {
+			protocol = "Http"
+		}
+	} else if err != annotations.ErrMissingAnnotation {
+		return network.ApplicationGatewayProbe{}, err
+	}
+
+	props := &network.ApplicationGatewayProbePropertiesFormat{
+		Protocol:           protocol,
+		Host:               to.StringPtr(host),
+		Path:               to.StringPtr(probePath),
+		Interval:           to.Int32Ptr(defaultProbeInterval),
+		Timeout:            to.Int32Ptr(defaultProbeTimeout),
+		UnhealthyThreshold: to.Int32Ptr(defaultProbeUnhealthyThreshold),
+	}
+
+	if httpGet := cb.podProbeForBackend(ingress.Namespace, path.Backend); httpGet != nil {
+		props.Path = to.StringPtr(httpGet.HTTPGet.Path)
+		if podProbeHost := httpGetHost(httpGet.HTTPGet); podProbeHost != "" {
+			props.Host = to.StringPtr(podProbeHost)
+		}
+		if !backendProtocolSet && httpGet.HTTPGet.Scheme == v1.URISchemeHTTPS {
+			props.Protocol = "Https"
+		}
+		if httpGet.PeriodSeconds > 0 {
+			props.Interval = to.Int32Ptr(httpGet.PeriodSeconds)
+		}
+		if httpGet.TimeoutSeconds > 0 {
+			props.Timeout = to.Int32Ptr(httpGet.TimeoutSeconds)
+		}
+		if httpGet.FailureThreshold > 0 {
+			props.UnhealthyThreshold = to.Int32Ptr(httpGet.FailureThreshold)
+		}
+	}
+
+	if err := applyHealthProbeAnnotations(ingress, props); err != nil {
+		return network.ApplicationGatewayProbe{}, err
+	}
+
+	if pick, err := annotations.HealthProbePickHostFromBackendHTTPSettings(ingress); err == nil {
+		if pick {
+			props.PickHostNameFromBackendHTTPSettings = to.BoolPtr(true)
+			props.Host = nil
+		}
+	} else if err != annotations.ErrMissingAnnotation {
+		return network.ApplicationGatewayProbe{}, err
+	}
+
+	return network.ApplicationGatewayProbe{
+		Name:                                    to.StringPtr(generateProbeName(ingress.Namespace, path.Backend.ServicePort, ingress.Name)),
+		ApplicationGatewayProbePropertiesFormat: props,
+	}, nil
+}
+
+// applyHealthProbeAnnotations overrides props with the values of any `health-probe-*`
+// annotations present on ingress, validating them as it goes. The
+// health-probe-pick-host-from-backend-http-settings annotation is not handled here;
+// healthProbeForPath applies it, so the default probe ignores it.
+func applyHealthProbeAnnotations(ingress *v1beta1.Ingress, props *network.ApplicationGatewayProbePropertiesFormat) error {
+	if path, err := annotations.HealthProbePath(ingress); err == nil {
+		props.Path = to.StringPtr(path)
+	} else if err != annotations.ErrMissingAnnotation {
+		return err
+	}
+
+	if host, err := annotations.HealthProbeHostName(ingress); err == nil {
+		props.Host = to.StringPtr(host)
+	} else if err != annotations.ErrMissingAnnotation {
+		return err
+	}
+
+	if interval, err := annotations.HealthProbeInterval(ingress); err == nil {
+		props.Interval = to.Int32Ptr(interval)
+	} else if err != annotations.ErrMissingAnnotation {
+		return err
+	}
+
+	if timeout, err := annotations.HealthProbeTimeout(ingress); err == nil {
+		props.Timeout = to.Int32Ptr(timeout)
+	} else if err != annotations.ErrMissingAnnotation {
+		return err
+	}
+
+	if threshold, err := annotations.HealthProbeUnhealthyThreshold(ingress); err == nil {
+		props.UnhealthyThreshold = to.Int32Ptr(threshold)
+	} else if err != annotations.ErrMissingAnnotation {
+		return err
+	}
+
+	match, err := buildHealthResponseMatch(ingress)
+	if err != nil {
+		return err
+	}
+	props.Match = match
+
+	return nil
+}
+
+// buildHealthResponseMatch assembles the Match field from the status-codes and
+// body-match annotations, returning nil when neither is set.
+func buildHealthResponseMatch(ingress *v1beta1.Ingress) (*network.ApplicationGatewayProbeHealthResponseMatch, error) {
+	statusCodes, err := annotations.HealthProbeStatusCodes(ingress)
+	if err != nil && err != annotations.ErrMissingAnnotation {
+		return nil, err
+	}
+
+	bodyMatch, err := annotations.HealthProbeBodyMatch(ingress)
+	if err != nil && err != annotations.ErrMissingAnnotation {
+		return nil, err
+	}
+
+	if statusCodes == "" && bodyMatch == "" {
+		return nil, nil
+	}
+
+	match := &network.ApplicationGatewayProbeHealthResponseMatch{}
+	if statusCodes != "" {
+		codes := strings.Split(statusCodes, ",")
+		for i := range codes {
+			codes[i] = strings.TrimSpace(codes[i])
+		}
+		match.StatusCodes = &codes
+	}
+	if bodyMatch != "" {
+		match.Body = to.StringPtr(bodyMatch)
+	}
+	return match, nil
+}
+
+// podProbeForBackend resolves the Service fronted by backend within namespace, follows its
+// Endpoints to a backing Pod, and returns that Pod's readiness probe (falling back to its
+// liveness probe) for the container port the backend targets -- provided it is an httpGet
+// probe. Non-HTTP probes (exec/tcp) and backends we can't resolve to a running Pod return
+// nil, leaving the caller to fall back to the ARM defaults.
+func (cb *ConfigBuilder) podProbeForBackend(namespace string, backend v1beta1.IngressBackend) *v1.Probe {
+	targetPort, ok := cb.resolveTargetPort(namespace, backend)
+	if !ok {
+		return nil
+	}
+
+	pod, ok := cb.findPodForService(namespace, backend.ServiceName)
+	if !ok {
+		return nil
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if !containerPortMatches(port, targetPort) {
+				continue
+			}
+			if probe := container.ReadinessProbe; probe != nil && probe.HTTPGet != nil {
+				return probe
+			}
+			if probe := container.LivenessProbe; probe != nil && probe.HTTPGet != nil {
+				return probe
+			}
+		}
+	}
+	return nil
+}
+
+// resolveTargetPort looks up the Service named by backend within namespace and returns the
+// container port its matching ServicePort targets, numerically or by name.
+func (cb *ConfigBuilder) resolveTargetPort(namespace string, backend v1beta1.IngressBackend) (intstr.IntOrString, bool) {
+	port, ok := cb.findServicePort(namespace, backend)
+	if !ok {
+		return intstr.IntOrString{}, false
+	}
+	return port.TargetPort, true
+}
+
+// containerPortMatches reports whether a Pod's ContainerPort is the one targetPort (a
+// Service's TargetPort) refers to: numerically, or by name for named container ports.
+func containerPortMatches(port v1.ContainerPort, targetPort intstr.IntOrString) bool {
+	if targetPort.Type == intstr.String {
+		return port.Name == targetPort.StrVal
+	}
+	return port.ContainerPort == targetPort.IntVal
+}
+
+// findServicePort looks up the Service named by backend within namespace and returns the
+// ServicePort it references, numerically or by name. Scoping to namespace keeps two
+// same-named Services in different namespaces from being confused with one another.
+func (cb *ConfigBuilder) findServicePort(namespace string, backend v1beta1.IngressBackend) (v1.ServicePort, bool) {
+	for _, obj := range cb.k8sContext.Caches.Service.List() {
+		svc, ok := obj.(v1.Service)
+		if !ok {
+			continue
+		}
+		if svc.Namespace != namespace || svc.Name != backend.ServiceName {
+			continue
+		}
+		for _, port := range svc.Spec.Ports {
+			if servicePortMatches(port, backend.ServicePort) {
+				return port, true
+			}
+		}
+	}
+	return v1.ServicePort{}, false
+}
+
+// isHTTPSServicePort reports whether a ServicePort looks like it fronts TLS traffic,
+// going by the conventional "https" port name or the well-known 443 port number.
+func isHTTPSServicePort(port v1.ServicePort) bool {
+	return port.Name == "https" || port.Port == 443
+}
+
+// findPodForService follows a Service's Endpoints, both scoped to namespace, to one of the
+// Pods backing it.
+func (cb *ConfigBuilder) findPodForService(namespace, serviceName string) (*v1.Pod, bool) {
+	for _, obj := range cb.k8sContext.Caches.Endpoints.List() {
+		endpoints, ok := obj.(v1.Endpoints)
+		if !ok {
+			continue
+		}
+		if endpoints.Namespace != namespace || endpoints.Name != serviceName {
+			continue
+		}
+		for _, subset := range endpoints.Subsets {
+			for _, address := range subset.Addresses {
+				if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+					continue
+				}
+				if pod, ok := cb.findPodByName(namespace, address.TargetRef.Name); ok {
+					return pod, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+func (cb *ConfigBuilder) findPodByName(namespace, name string) (*v1.Pod, bool) {
+	for _, obj := range cb.k8sContext.Caches.Pods.List() {
+		pod, ok := obj.(v1.Pod)
+		if !ok {
+			continue
+		}
+		if pod.Namespace == namespace && pod.Name == name {
+			return &pod, true
+		}
+	}
+	return nil, false
+}
+
+// servicePortMatches reports whether a ServicePort satisfies an IngressBackend's
+// ServicePort reference, which may be a numeric port or a named port.
+func servicePortMatches(port v1.ServicePort, backendPort intstr.IntOrString) bool {
+	if backendPort.Type == intstr.String {
+		return port.Name == backendPort.StrVal
+	}
+	return port.Port == backendPort.IntVal
+}
+
+// httpGetHost returns the Host an httpGet probe will send, preferring the explicit Host
+// field and falling back to a "Host" header if that's how the probe is configured.
+func httpGetHost(httpGet *v1.HTTPGetAction) string {
+	if httpGet.Host != "" {
+		return httpGet.Host
+	}
+	for _, header := range httpGet.HTTPHeaders {
+		if header.Name == "Host" {
+			return header.Value
+		}
+	}
+	return ""
+}
+
+// generateProbeName derives a unique, human-greppable probe name from the owning
+// namespace, backend port, and Ingress name.
+func generateProbeName(namespace string, servicePort intstr.IntOrString, ingressName string) string {
+	var port string
+	if servicePort.Type == intstr.String {
+		port = servicePort.StrVal
+	} else {
+		port = fmt.Sprintf("%d", servicePort.IntVal)
+	}
+	return fmt.Sprintf("k8s-ag-ingress-%s-%s-pb-%s-%s-", namespace, port, namespace, ingressName)
+}
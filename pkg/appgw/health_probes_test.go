@@ -14,7 +14,10 @@ import (
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
 )
 
 func TestHealthProbes(t *testing.T) {
@@ -204,4 +207,741 @@ var _ = Describe("configure App Gateway health probes", func() {
 			Expect(*actual).To(ContainElement(expected[2]))
 		})
 	})
+
+	Context("looking at a Pod with an httpGet readinessProbe", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		service := v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "has-probe-svc"},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{
+						Port:       8080,
+						TargetPort: intstr.IntOrString{IntVal: 8080},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Service.Add(service)).To(BeNil())
+
+		endpoints := v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "has-probe-svc"},
+			Subsets: []v1.EndpointSubset{
+				{
+					Addresses: []v1.EndpointAddress{
+						{
+							IP:        "10.9.8.1",
+							TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "pod-with-probe"},
+						},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Endpoints.Add(endpoints)).To(BeNil())
+
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-with-probe"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:  "app",
+						Ports: []v1.ContainerPort{{ContainerPort: 8080}},
+						ReadinessProbe: &v1.Probe{
+							Handler: v1.Handler{
+								HTTPGet: &v1.HTTPGetAction{
+									Path: "/healthz",
+									Port: intstr.IntOrString{IntVal: 8080},
+								},
+							},
+							PeriodSeconds:    15,
+							TimeoutSeconds:   5,
+							FailureThreshold: 2,
+						},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Pods.Add(pod)).To(BeNil())
+
+		ingress := v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: testFixturesName},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					{
+						Host: "probe.contoso.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									{
+										Path: "/svc",
+										Backend: v1beta1.IngressBackend{
+											ServiceName: "has-probe-svc",
+											ServicePort: intstr.IntOrString{IntVal: 8080},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// !! Action !!
+		_, _ = cb.HealthProbesCollection([]*v1beta1.Ingress{&ingress})
+		actual := cb.appGwConfig.Probes
+
+		It("should translate the Pod's readinessProbe onto the generated probe", func() {
+			Expect(*actual).To(HaveLen(1))
+			probe := (*actual)[0]
+			Expect(*probe.Path).To(Equal("/healthz"))
+			Expect(*probe.Host).To(Equal("probe.contoso.com"))
+			Expect(*probe.Interval).To(Equal(int32(15)))
+			Expect(*probe.Timeout).To(Equal(int32(5)))
+			Expect(*probe.UnhealthyThreshold).To(Equal(int32(2)))
+		})
+	})
+
+	Context("looking at a Pod with an httpGet readinessProbe declaring Scheme: HTTPS", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		service := v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "has-https-probe-svc"},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{
+						Port:       8443,
+						TargetPort: intstr.IntOrString{IntVal: 8443},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Service.Add(service)).To(BeNil())
+
+		endpoints := v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "has-https-probe-svc"},
+			Subsets: []v1.EndpointSubset{
+				{
+					Addresses: []v1.EndpointAddress{
+						{
+							IP:        "10.9.8.3",
+							TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "pod-with-https-probe"},
+						},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Endpoints.Add(endpoints)).To(BeNil())
+
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-with-https-probe"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:  "app",
+						Ports: []v1.ContainerPort{{ContainerPort: 8443}},
+						ReadinessProbe: &v1.Probe{
+							Handler: v1.Handler{
+								HTTPGet: &v1.HTTPGetAction{
+									Path:   "/healthz",
+									Port:   intstr.IntOrString{IntVal: 8443},
+									Scheme: v1.URISchemeHTTPS,
+								},
+							},
+							PeriodSeconds:    15,
+							TimeoutSeconds:   5,
+							FailureThreshold: 2,
+						},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Pods.Add(pod)).To(BeNil())
+
+		ingress := v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: testFixturesName},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					{
+						Host: "https-probe.contoso.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									{
+										Path: "/svc",
+										Backend: v1beta1.IngressBackend{
+											ServiceName: "has-https-probe-svc",
+											ServicePort: intstr.IntOrString{IntVal: 8443},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// !! Action !!
+		_, _ = cb.HealthProbesCollection([]*v1beta1.Ingress{&ingress})
+		actual := cb.appGwConfig.Probes
+
+		It("should translate the Pod's httpGet Scheme onto the generated probe's Protocol", func() {
+			Expect(*actual).To(HaveLen(1))
+			probe := (*actual)[0]
+			Expect(probe.Protocol).To(Equal(network.ApplicationGatewayProtocol("Https")))
+		})
+	})
+
+	Context("looking at a Pod with an httpGet readinessProbe declaring Scheme: HTTPS and a conflicting backend-protocol annotation", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		service := v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "has-conflicting-probe-svc"},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{
+						Port:       8443,
+						TargetPort: intstr.IntOrString{IntVal: 8443},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Service.Add(service)).To(BeNil())
+
+		endpoints := v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "has-conflicting-probe-svc"},
+			Subsets: []v1.EndpointSubset{
+				{
+					Addresses: []v1.EndpointAddress{
+						{
+							IP:        "10.9.8.4",
+							TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "pod-with-conflicting-probe"},
+						},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Endpoints.Add(endpoints)).To(BeNil())
+
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-with-conflicting-probe"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:  "app",
+						Ports: []v1.ContainerPort{{ContainerPort: 8443}},
+						ReadinessProbe: &v1.Probe{
+							Handler: v1.Handler{
+								HTTPGet: &v1.HTTPGetAction{
+									Path:   "/healthz",
+									Port:   intstr.IntOrString{IntVal: 8443},
+									Scheme: v1.URISchemeHTTPS,
+								},
+							},
+							PeriodSeconds:    15,
+							TimeoutSeconds:   5,
+							FailureThreshold: 2,
+						},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Pods.Add(pod)).To(BeNil())
+
+		ingress := v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: testFixturesName,
+				Annotations: map[string]string{
+					annotations.BackendProtocolKey: "http",
+				},
+			},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					{
+						Host: "conflicting-probe.contoso.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									{
+										Path: "/svc",
+										Backend: v1beta1.IngressBackend{
+											ServiceName: "has-conflicting-probe-svc",
+											ServicePort: intstr.IntOrString{IntVal: 8443},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// !! Action !!
+		_, _ = cb.HealthProbesCollection([]*v1beta1.Ingress{&ingress})
+		actual := cb.appGwConfig.Probes
+
+		It("should let the explicit backend-protocol annotation win over the Pod's probe Scheme", func() {
+			Expect(*actual).To(HaveLen(1))
+			probe := (*actual)[0]
+			Expect(probe.Protocol).To(Equal(network.ApplicationGatewayProtocol("Http")))
+		})
+	})
+
+	Context("looking at a Pod without an HTTP probe", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		service := v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-probe-svc"},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{
+						Port:       9090,
+						TargetPort: intstr.IntOrString{IntVal: 9090},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Service.Add(service)).To(BeNil())
+
+		endpoints := v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-probe-svc"},
+			Subsets: []v1.EndpointSubset{
+				{
+					Addresses: []v1.EndpointAddress{
+						{
+							IP:        "10.9.8.2",
+							TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "pod-without-probe"},
+						},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Endpoints.Add(endpoints)).To(BeNil())
+
+		// A Pod whose container declares the target port but no readiness/liveness
+		// probe at all -- the generated probe should fall back to the ARM defaults.
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-without-probe"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:  "app",
+						Ports: []v1.ContainerPort{{ContainerPort: 9090}},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Pods.Add(pod)).To(BeNil())
+
+		ingress := v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: testFixturesName},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					{
+						Host: "noprobe.contoso.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									{
+										Path: "/svc",
+										Backend: v1beta1.IngressBackend{
+											ServiceName: "no-probe-svc",
+											ServicePort: intstr.IntOrString{IntVal: 9090},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// !! Action !!
+		_, _ = cb.HealthProbesCollection([]*v1beta1.Ingress{&ingress})
+		actual := cb.appGwConfig.Probes
+
+		It("should fall back to the ARM defaults", func() {
+			Expect(*actual).To(HaveLen(1))
+			probe := (*actual)[0]
+			Expect(*probe.Path).To(Equal("/svc"))
+			Expect(*probe.Host).To(Equal("noprobe.contoso.com"))
+			Expect(*probe.Interval).To(Equal(int32(30)))
+			Expect(*probe.Timeout).To(Equal(int32(30)))
+			Expect(*probe.UnhealthyThreshold).To(Equal(int32(3)))
+		})
+	})
+
+	Context("looking at health-probe-* annotation overrides", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		ingress := v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: testFixturesName,
+				Annotations: map[string]string{
+					annotations.HealthProbePathKey:               "/custom-health",
+					annotations.HealthProbeHostNameKey:           "probe.internal",
+					annotations.HealthProbeIntervalKey:           "5",
+					annotations.HealthProbeTimeoutKey:            "10",
+					annotations.HealthProbeUnhealthyThresholdKey: "8",
+					annotations.HealthProbeStatusCodesKey:        "200-399",
+					annotations.HealthProbeBodyMatchKey:          "OK",
+				},
+			},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					{
+						Host: testFixturesHost,
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									{
+										Path: testFixturesPath,
+										Backend: v1beta1.IngressBackend{
+											ServiceName: testFixturesName,
+											ServicePort: intstr.IntOrString{IntVal: 8080},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// !! Action !!
+		_, err := cb.HealthProbesCollection([]*v1beta1.Ingress{&ingress})
+		actual := cb.appGwConfig.Probes
+
+		It("should apply the annotation overrides", func() {
+			Expect(err).To(BeNil())
+			Expect(*actual).To(HaveLen(1))
+			probe := (*actual)[0]
+			Expect(*probe.Path).To(Equal("/custom-health"))
+			Expect(*probe.Host).To(Equal("probe.internal"))
+			Expect(*probe.Interval).To(Equal(int32(5)))
+			Expect(*probe.Timeout).To(Equal(int32(10)))
+			Expect(*probe.UnhealthyThreshold).To(Equal(int32(8)))
+			Expect(probe.Match).ToNot(BeNil())
+			Expect(*probe.Match.StatusCodes).To(Equal([]string{"200-399"}))
+			Expect(*probe.Match.Body).To(Equal("OK"))
+		})
+	})
+
+	Context("looking at an invalid health-probe-interval annotation", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		ingress := v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: testFixturesName,
+				Annotations: map[string]string{
+					annotations.HealthProbeIntervalKey: "not-a-number",
+				},
+			},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					{
+						Host: testFixturesHost,
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									{
+										Path: testFixturesPath,
+										Backend: v1beta1.IngressBackend{
+											ServiceName: testFixturesName,
+											ServicePort: intstr.IntOrString{IntVal: 8080},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// !! Action !!
+		_, err := cb.HealthProbesCollection([]*v1beta1.Ingress{&ingress})
+
+		It("should surface a validation error", func() {
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("looking at Ingresses with mixed ingress.class annotations", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		newIngressFixture := func(name, host, ingressClass string) v1beta1.Ingress {
+			ingress := v1beta1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: v1beta1.IngressSpec{
+					Rules: []v1beta1.IngressRule{
+						{
+							Host: host,
+							IngressRuleValue: v1beta1.IngressRuleValue{
+								HTTP: &v1beta1.HTTPIngressRuleValue{
+									Paths: []v1beta1.HTTPIngressPath{
+										{
+											Path: testFixturesPath,
+											Backend: v1beta1.IngressBackend{
+												ServiceName: testFixturesName,
+												ServicePort: intstr.IntOrString{IntVal: 8080},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			if ingressClass != "" {
+				ingress.Annotations = map[string]string{annotations.IngressClassKey: ingressClass}
+			}
+			return ingress
+		}
+
+		unannotated := newIngressFixture("unannotated", "unannotated.contoso.com", "")
+		matching := newIngressFixture("matching", "matching.contoso.com", DefaultIngressClass)
+		otherController := newIngressFixture("other-controller", "other.contoso.com", "some-other-controller")
+
+		// !! Action !!
+		_, err := cb.HealthProbesCollection([]*v1beta1.Ingress{&unannotated, &matching, &otherController})
+		actual := cb.appGwConfig.Probes
+
+		It("should only contribute probes for the unannotated and matching Ingresses", func() {
+			Expect(err).To(BeNil())
+			Expect(*actual).To(HaveLen(2))
+			hosts := []string{*(*actual)[0].Host, *(*actual)[1].Host}
+			Expect(hosts).To(ConsistOf("unannotated.contoso.com", "matching.contoso.com"))
+		})
+	})
+
+	Context("looking at a named TLS service port", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		service := v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls-svc"},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{port2},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Service.Add(service)).To(BeNil())
+
+		ingress := v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: testFixturesName},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					{
+						Host: testFixturesHost,
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									{
+										Path: testFixturesPath,
+										Backend: v1beta1.IngressBackend{
+											ServiceName: "tls-svc",
+											ServicePort: intstr.IntOrString{Type: intstr.String, StrVal: "https"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// !! Action !!
+		_, err := cb.HealthProbesCollection([]*v1beta1.Ingress{&ingress})
+		actual := cb.appGwConfig.Probes
+
+		It("should pick the Https protocol from the named service port", func() {
+			Expect(err).To(BeNil())
+			Expect(*actual).To(HaveLen(1))
+			Expect((*actual)[0].Protocol).To(Equal(network.ApplicationGatewayProtocol("Https")))
+		})
+	})
+
+	Context("looking at a Pod with an httpGet readinessProbe behind a named service TargetPort", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		service := v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "has-named-port-probe-svc"},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{
+						Port:       443,
+						TargetPort: intstr.IntOrString{Type: intstr.String, StrVal: "https-port"},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Service.Add(service)).To(BeNil())
+
+		endpoints := v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "has-named-port-probe-svc"},
+			Subsets: []v1.EndpointSubset{
+				{
+					Addresses: []v1.EndpointAddress{
+						{
+							IP:        "10.9.8.5",
+							TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "pod-with-named-port-probe"},
+						},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Endpoints.Add(endpoints)).To(BeNil())
+
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-with-named-port-probe"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:  "app",
+						Ports: []v1.ContainerPort{{Name: "https-port", ContainerPort: 8443}},
+						ReadinessProbe: &v1.Probe{
+							Handler: v1.Handler{
+								HTTPGet: &v1.HTTPGetAction{
+									Path: "/healthz",
+									Port: intstr.IntOrString{IntVal: 8443},
+								},
+							},
+							PeriodSeconds:    15,
+							TimeoutSeconds:   5,
+							FailureThreshold: 2,
+						},
+					},
+				},
+			},
+		}
+		Expect(cb.k8sContext.Caches.Pods.Add(pod)).To(BeNil())
+
+		ingress := v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: testFixturesName},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					{
+						Host: "named-port-probe.contoso.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									{
+										Path: "/svc",
+										Backend: v1beta1.IngressBackend{
+											ServiceName: "has-named-port-probe-svc",
+											ServicePort: intstr.IntOrString{IntVal: 443},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// !! Action !!
+		_, _ = cb.HealthProbesCollection([]*v1beta1.Ingress{&ingress})
+		actual := cb.appGwConfig.Probes
+
+		It("should translate the Pod's readinessProbe onto the generated probe via the named TargetPort", func() {
+			Expect(*actual).To(HaveLen(1))
+			probe := (*actual)[0]
+			Expect(*probe.Path).To(Equal("/healthz"))
+			Expect(*probe.Interval).To(Equal(int32(15)))
+		})
+	})
+
+	Context("looking at the backend-protocol annotation", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		ingress := v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: testFixturesName,
+				Annotations: map[string]string{
+					annotations.BackendProtocolKey: "https",
+				},
+			},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					{
+						Host: testFixturesHost,
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									{
+										Path: testFixturesPath,
+										Backend: v1beta1.IngressBackend{
+											ServiceName: testFixturesName,
+											ServicePort: intstr.IntOrString{IntVal: 8080},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// !! Action !!
+		_, err := cb.HealthProbesCollection([]*v1beta1.Ingress{&ingress})
+		actual := cb.appGwConfig.Probes
+
+		It("should pick the Https protocol from the annotation", func() {
+			Expect(err).To(BeNil())
+			Expect(*actual).To(HaveLen(1))
+			Expect((*actual)[0].Protocol).To(Equal(network.ApplicationGatewayProtocol("Https")))
+		})
+	})
+
+	Context("looking at the health-probe-pick-host-from-backend-http-settings annotation", func() {
+		cb := makeConfigBuilderTestFixture(nil)
+
+		ingress := v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: testFixturesName,
+				Annotations: map[string]string{
+					annotations.HealthProbePickHostFromBackendHTTPSettingsKey: "true",
+				},
+			},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					{
+						Host: testFixturesHost,
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									{
+										Path: testFixturesPath,
+										Backend: v1beta1.IngressBackend{
+											ServiceName: testFixturesName,
+											ServicePort: intstr.IntOrString{IntVal: 8080},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// !! Action !!
+		_, err := cb.HealthProbesCollection([]*v1beta1.Ingress{&ingress})
+		actual := cb.appGwConfig.Probes
+
+		It("should set PickHostNameFromBackendHTTPSettings and clear Host", func() {
+			Expect(err).To(BeNil())
+			Expect(*actual).To(HaveLen(1))
+			probe := (*actual)[0]
+			Expect(probe.PickHostNameFromBackendHTTPSettings).To(Equal(to.BoolPtr(true)))
+			Expect(probe.Host).To(BeNil())
+		})
+	})
 })
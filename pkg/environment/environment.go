@@ -0,0 +1,28 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+// Package environment resolves process-wide controller configuration that can be set
+// either via a CLI flag or, for container deployments, an environment variable.
+package environment
+
+import (
+	"flag"
+	"os"
+)
+
+// EnvVarIngressClass is the environment variable fallback for the `--ingress-class` flag.
+const EnvVarIngressClass = "AGIC_INGRESS_CLASS"
+
+var ingressClassFlag = flag.String("ingress-class", "", "the kubernetes.io/ingress.class value this controller acts on (defaults to "+EnvVarIngressClass+", then azure/application-gateway)")
+
+// GetIngressClass returns the configured ingress class: the `--ingress-class` flag if set,
+// otherwise the AGIC_INGRESS_CLASS environment variable, otherwise "". Callers fall back to
+// appgw.DefaultIngressClass.
+func GetIngressClass() string {
+	if *ingressClassFlag != "" {
+		return *ingressClassFlag
+	}
+	return os.Getenv(EnvVarIngressClass)
+}
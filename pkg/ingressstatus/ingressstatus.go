@@ -0,0 +1,122 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+// Package ingressstatus writes the Application Gateway's frontend address back into the
+// `status.loadBalancer.ingress` field of every Ingress this controller manages -- the
+// equivalent of Traefik's `ingressEndpoint` configuration.
+package ingressstatus
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Config selects how the frontend address published to Ingress status is determined.
+// Exactly one of Hostname, IP, or PublishedService must be set.
+type Config struct {
+	// Hostname is a static DNS name to publish, e.g. the Application Gateway's FQDN.
+	Hostname string
+
+	// IP is a static IP address to publish, e.g. the Application Gateway's public IP.
+	IP string
+
+	// PublishedService is a "namespace/name" reference to a Service whose own
+	// status.loadBalancer.ingress is copied through verbatim. Typical use: a
+	// type=LoadBalancer Service fronting the Application Gateway.
+	PublishedService string
+}
+
+// Validate ensures exactly one of the three knobs is set.
+func (c Config) Validate() error {
+	set := 0
+	if c.Hostname != "" {
+		set++
+	}
+	if c.IP != "" {
+		set++
+	}
+	if c.PublishedService != "" {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("ingressstatus: exactly one of Hostname, IP, or PublishedService must be set, got %d", set)
+	}
+	if c.PublishedService != "" {
+		if parts := strings.Split(c.PublishedService, "/"); len(parts) != 2 {
+			return fmt.Errorf("ingressstatus: PublishedService must be of the form \"namespace/name\", got %q", c.PublishedService)
+		}
+	}
+	return nil
+}
+
+// Updater patches Ingress status with the frontend address selected by a Config.
+type Updater struct {
+	client kubernetes.Interface
+	config Config
+}
+
+// NewUpdater creates an Updater that writes status onto Ingresses using client,
+// publishing the address described by config. It returns an error if config fails
+// to Validate.
+func NewUpdater(client kubernetes.Interface, config Config) (*Updater, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Updater{client: client, config: config}, nil
+}
+
+// Resolve computes the status.loadBalancer.ingress value this Updater should publish.
+func (u *Updater) Resolve() ([]v1.LoadBalancerIngress, error) {
+	switch {
+	case u.config.Hostname != "":
+		return []v1.LoadBalancerIngress{{Hostname: u.config.Hostname}}, nil
+	case u.config.IP != "":
+		return []v1.LoadBalancerIngress{{IP: u.config.IP}}, nil
+	case u.config.PublishedService != "":
+		return u.resolvePublishedService()
+	default:
+		return nil, fmt.Errorf("ingressstatus: no ingressEndpoint configured")
+	}
+}
+
+func (u *Updater) resolvePublishedService() ([]v1.LoadBalancerIngress, error) {
+	parts := strings.SplitN(u.config.PublishedService, "/", 2)
+	namespace, name := parts[0], parts[1]
+
+	svc, err := u.client.CoreV1().Services(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("ingressstatus: getting published service %s: %v", u.config.PublishedService, err)
+	}
+	return svc.Status.LoadBalancer.Ingress, nil
+}
+
+// UpdateIngressStatus patches ingress's status.loadBalancer.ingress with the resolved
+// frontend address. It is called after a successful Application Gateway (ARM) update.
+func (u *Updater) UpdateIngressStatus(ingress *v1beta1.Ingress) error {
+	lbIngress, err := u.Resolve()
+	if err != nil {
+		return err
+	}
+	return u.patchStatus(ingress, lbIngress)
+}
+
+// ClearIngressStatus removes the frontend address from ingress's status. It is called
+// when the Ingress is deleted so stale addresses don't linger.
+func (u *Updater) ClearIngressStatus(ingress *v1beta1.Ingress) error {
+	return u.patchStatus(ingress, nil)
+}
+
+func (u *Updater) patchStatus(ingress *v1beta1.Ingress, lbIngress []v1.LoadBalancerIngress) error {
+	updated := ingress.DeepCopy()
+	updated.Status.LoadBalancer.Ingress = lbIngress
+
+	_, err := u.client.ExtensionsV1beta1().Ingresses(updated.Namespace).UpdateStatus(updated)
+	return err
+}
@@ -0,0 +1,106 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package ingressstatus
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIngressStatus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Test writing Application Gateway status back onto Ingress resources")
+}
+
+var _ = Describe("write Ingress status", func() {
+
+	ingress := v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "-name-",
+			Namespace: "-namespace-",
+		},
+	}
+
+	Context("with a static hostname configured", func() {
+		client := fake.NewSimpleClientset(ingress.DeepCopy())
+		updater, newErr := NewUpdater(client, Config{Hostname: "agic.contoso.com"})
+
+		// !! Action !!
+		err := updater.UpdateIngressStatus(&ingress)
+
+		It("should patch the Ingress status with the configured hostname", func() {
+			Expect(newErr).To(BeNil())
+			Expect(err).To(BeNil())
+
+			actual, getErr := client.ExtensionsV1beta1().Ingresses(ingress.Namespace).Get(ingress.Name, metav1.GetOptions{})
+			Expect(getErr).To(BeNil())
+			Expect(actual.Status.LoadBalancer.Ingress).To(Equal([]v1.LoadBalancerIngress{{Hostname: "agic.contoso.com"}}))
+		})
+	})
+
+	Context("with a published Service configured", func() {
+		publishedService := v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "frontend-svc", Namespace: "-namespace-"},
+			Status: v1.ServiceStatus{
+				LoadBalancer: v1.LoadBalancerStatus{
+					Ingress: []v1.LoadBalancerIngress{{IP: "10.1.2.3"}},
+				},
+			},
+		}
+		client := fake.NewSimpleClientset(ingress.DeepCopy(), publishedService.DeepCopy())
+		updater, newErr := NewUpdater(client, Config{PublishedService: "-namespace-/frontend-svc"})
+
+		// !! Action !!
+		err := updater.UpdateIngressStatus(&ingress)
+
+		It("should copy through the published Service's status", func() {
+			Expect(newErr).To(BeNil())
+			Expect(err).To(BeNil())
+
+			actual, getErr := client.ExtensionsV1beta1().Ingresses(ingress.Namespace).Get(ingress.Name, metav1.GetOptions{})
+			Expect(getErr).To(BeNil())
+			Expect(actual.Status.LoadBalancer.Ingress).To(Equal([]v1.LoadBalancerIngress{{IP: "10.1.2.3"}}))
+		})
+	})
+
+	Context("clearing status on Ingress deletion", func() {
+		withStatus := ingress.DeepCopy()
+		withStatus.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "10.1.2.3"}}
+
+		client := fake.NewSimpleClientset(withStatus)
+		updater, newErr := NewUpdater(client, Config{IP: "10.1.2.3"})
+
+		// !! Action !!
+		err := updater.ClearIngressStatus(withStatus)
+
+		It("should remove the published address from the Ingress status", func() {
+			Expect(newErr).To(BeNil())
+			Expect(err).To(BeNil())
+
+			actual, getErr := client.ExtensionsV1beta1().Ingresses(withStatus.Namespace).Get(withStatus.Name, metav1.GetOptions{})
+			Expect(getErr).To(BeNil())
+			Expect(actual.Status.LoadBalancer.Ingress).To(BeEmpty())
+		})
+	})
+
+	Context("with no ingressEndpoint knob set", func() {
+		It("should fail validation", func() {
+			Expect(Config{}.Validate()).ToNot(BeNil())
+		})
+	})
+
+	Context("with more than one ingressEndpoint knob set", func() {
+		It("should fail validation", func() {
+			Expect(Config{Hostname: "agic.contoso.com", IP: "10.1.2.3"}.Validate()).ToNot(BeNil())
+		})
+	})
+})